@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+)
+
+// entryResult records the outcome of syncing one manifest entry.
+type entryResult struct {
+	Repo string
+	Err  error
+}
+
+// runManifest syncs every entry in the manifest, up to config.Parallel at a time,
+// prints a per-repo summary, and reports whether any entry failed. Each entry
+// inherits config's host, provider, and conflict-handling settings.
+func runManifest(config *AppConfig, manifest *Manifest) bool {
+	parallel := config.Parallel
+	if parallel < 1 {
+		parallel = 1
+	}
+
+	results := make([]entryResult, len(manifest.Entries))
+	sem := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+
+	for i, entry := range manifest.Entries {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, entry ManifestEntry) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = entryResult{Repo: entry.Repo, Err: syncManifestEntry(config, entry)}
+		}(i, entry)
+	}
+	wg.Wait()
+
+	failed := false
+	fmt.Println("\nBatch sync summary:")
+	for _, result := range results {
+		if result.Err != nil {
+			failed = true
+			fmt.Printf("✗ %s: %v\n", result.Repo, result.Err)
+		} else {
+			fmt.Printf("✓ %s\n", result.Repo)
+		}
+	}
+	return failed
+}
+
+// syncManifestEntry runs every branch mapping declared for a single manifest entry,
+// against config's host/provider settings.
+func syncManifestEntry(config *AppConfig, entry ManifestEntry) error {
+	dir, cleanup, err := localCloneDir(entry.Repo, config.Host)
+	if err != nil {
+		return err
+	}
+	defer cleanup()
+
+	label := fmt.Sprintf("[%s] ", entry.Repo)
+	for _, branch := range entry.Branches {
+		if err := runSync(entryAppConfig(config, entry, branch), dir, label); err != nil {
+			return fmt.Errorf("%s: %w", branch.OriginBranch, err)
+		}
+	}
+	return nil
+}
+
+// entryAppConfig builds the AppConfig for one branch mapping of a manifest
+// entry: it inherits host, provider, and conflict-handling settings from the
+// top-level config, and applies the entry's remote-name override and the
+// branch's own upstream/origin/rebase/force settings.
+func entryAppConfig(config *AppConfig, entry ManifestEntry, branch BranchMapping) *AppConfig {
+	remoteName := entry.RemoteName
+	if remoteName == "" {
+		remoteName = "upstream"
+	}
+
+	entryConfig := *config
+	entryConfig.UpstreamBranch = branch.UpstreamBranch
+	entryConfig.OriginBranch = branch.OriginBranch
+	entryConfig.Rebase = branch.Rebase
+	entryConfig.ForcePush = branch.Force
+	entryConfig.RemoteName = remoteName
+	return &entryConfig
+}
+
+// localCloneDir resolves a manifest entry's repo field to a local working
+// directory: a path that already exists on disk is used as-is, otherwise
+// repo is treated as an "owner/name" repo on host and cloned into a
+// temporary directory that the returned cleanup removes once the caller is
+// done syncing it.
+func localCloneDir(repo, host string) (dir string, cleanup func(), err error) {
+	if info, statErr := os.Stat(repo); statErr == nil && info.IsDir() {
+		return repo, func() {}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "gh-fork-sync-*")
+	if err != nil {
+		return "", nil, fmt.Errorf("creating temp clone dir for %s: %v", repo, err)
+	}
+	cleanup = func() { os.RemoveAll(tmpDir) }
+
+	cloneURL := fmt.Sprintf("https://%s/%s.git", host, repo)
+	cmd := exec.Command("git", "clone", cloneURL, tmpDir)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("cloning %s: %v\n%s", repo, err, out)
+	}
+	return tmpDir, cleanup, nil
+}
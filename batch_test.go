@@ -0,0 +1,47 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+func TestEntryAppConfig(t *testing.T) {
+	config := &AppConfig{Host: "gitlab.example.com", HostType: "gitlab", Shell: true, OnConflict: "theirs"}
+	entry := ManifestEntry{Repo: "owner/repo"}
+	branch := BranchMapping{UpstreamBranch: "main", OriginBranch: "main", Rebase: true, Force: true}
+
+	got := entryAppConfig(config, entry, branch)
+
+	if got.Host != config.Host || got.HostType != config.HostType || got.Shell != config.Shell || got.OnConflict != config.OnConflict {
+		t.Errorf("entry config didn't inherit top-level settings: %+v", got)
+	}
+	if got.RemoteName != "upstream" {
+		t.Errorf("RemoteName = %q, want default %q", got.RemoteName, "upstream")
+	}
+	if got.UpstreamBranch != "main" || got.OriginBranch != "main" || !got.Rebase || !got.ForcePush {
+		t.Errorf("branch mapping not applied: %+v", got)
+	}
+
+	entry.RemoteName = "parent"
+	got = entryAppConfig(config, entry, branch)
+	if got.RemoteName != "parent" {
+		t.Errorf("RemoteName override = %q, want %q", got.RemoteName, "parent")
+	}
+}
+
+func TestLocalCloneDirExistingPath(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	dir, cleanup, err := localCloneDir(tmpDir, "github.com")
+	if err != nil {
+		t.Fatalf("localCloneDir() error = %v", err)
+	}
+	defer cleanup()
+
+	if dir != tmpDir {
+		t.Errorf("dir = %v, want %v (existing directory should be used as-is)", dir, tmpDir)
+	}
+	if _, err := os.Stat(dir); err != nil {
+		t.Errorf("dir %v should still exist: %v", dir, err)
+	}
+}
@@ -1,13 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"os/exec"
-	"strings"
+	"os"
+	"sync"
 
-	"github.com/cli/go-gh/v2/pkg/api"
-	"github.com/cli/safeexec"
+	"github.com/ankddev/gh-fork-sync/pkg/forksync"
 )
 
 // AppConfig holds the configuration for the fork-sync command
@@ -17,22 +18,24 @@ type AppConfig struct {
 	Rebase         bool   // Rebase instead of merge
 	ForcePush      bool   // Force push to origin
 	DryRun         bool   // Print commands without executing them
+	ConfigFile     string // Path to a manifest describing many forks to sync in one run
+	Parallel       int    // Number of manifest entries to sync concurrently
+	LFS            bool   // Sync Git LFS objects along with the branch
+	OnConflict     string // How to handle merge/rebase conflicts: abort, skip, theirs, ours, prompt
+	Host           string // Git host to talk to, e.g. github.com or gitlab.example.com (overrides GH_HOST)
+	HostType       string // Git hosting provider type: github, gitlab, gitea, bitbucket
+	RemoteName     string // Name of the git remote to point at the fork's parent
+	ReplaceRemote  bool   // Update RemoteName's URL if it already exists and points elsewhere
+	Shell          bool   // Force every step through the git binary instead of go-git
 }
 
-// RepoInfo holds information about a GitHub repository
-type RepoInfo struct {
-	FullName string `json:"full_name"`
-	Fork     bool   `json:"fork"`
-	Parent   struct {
-		FullName string `json:"full_name"`
-		CloneURL string `json:"clone_url"`
-	} `json:"parent"`
-}
-
-// GitCommand represents a git command to be executed
-type GitCommand struct {
-	Args        []string
-	Description string
+// defaultHost returns the git host to use when --host isn't set, mirroring gh's
+// own GH_HOST enterprise support.
+func defaultHost() string {
+	if host := os.Getenv("GH_HOST"); host != "" {
+		return host
+	}
+	return "github.com"
 }
 
 // parseFlags parses command line flags and returns the configuration
@@ -44,6 +47,15 @@ func parseFlags() *AppConfig {
 	flag.BoolVar(&config.Rebase, "rebase", false, "Rebase instead of merge")
 	flag.BoolVar(&config.ForcePush, "force", false, "Force push to origin")
 	flag.BoolVar(&config.DryRun, "dry-run", false, "Print commands without executing them")
+	flag.StringVar(&config.ConfigFile, "config", "", "Path to a manifest describing many forks to sync in one run")
+	flag.IntVar(&config.Parallel, "parallel", 1, "Number of manifest entries to sync concurrently (used with --config)")
+	flag.BoolVar(&config.LFS, "lfs", false, "Also fetch and push Git LFS objects")
+	flag.StringVar(&config.OnConflict, "on-conflict", "abort", "How to handle merge/rebase conflicts: abort, skip, theirs, ours, prompt")
+	flag.StringVar(&config.Host, "host", defaultHost(), "Git host to use, e.g. github.com or gitlab.example.com (overrides GH_HOST)")
+	flag.StringVar(&config.HostType, "host-type", "github", "Git hosting provider type: github, gitlab, gitea, bitbucket")
+	flag.StringVar(&config.RemoteName, "remote-name", "upstream", "Name of the git remote to point at the fork's parent")
+	flag.BoolVar(&config.ReplaceRemote, "replace-remote", false, "Update remote-name's URL if it already exists and points elsewhere")
+	flag.BoolVar(&config.Shell, "shell", false, "Shell out to the git binary for every step instead of using go-git (needed for non-fast-forward merges; --rebase and --lfs always use it)")
 
 	// Add custom usage message
 	flag.Usage = func() {
@@ -63,113 +75,93 @@ func parseFlags() *AppConfig {
 		fmt.Println("  $ gh fork-sync --force")
 		fmt.Println("\n  # Preview the commands without executing them")
 		fmt.Println("  $ gh fork-sync --dry-run")
+		fmt.Println("\n  # Sync every fork listed in a manifest, four at a time")
+		fmt.Println("  $ gh fork-sync --config forks.yml --parallel 4")
+		fmt.Println("\n  # Sync a fork that tracks Git LFS objects")
+		fmt.Println("  $ gh fork-sync --lfs")
+		fmt.Println("\n  # Resolve conflicts by always keeping upstream's version")
+		fmt.Println("  $ gh fork-sync --rebase --on-conflict=theirs")
+		fmt.Println("\n  # Sync a fork hosted on a GitLab instance")
+		fmt.Println("  $ gh fork-sync --host gitlab.example.com --host-type gitlab")
+		fmt.Println("\n  # Use a different remote name and update it if it's stale")
+		fmt.Println("  $ gh fork-sync --remote-name parent --replace-remote")
+		fmt.Println("\n  # Force every step through the git binary, e.g. for a true (non-fast-forward) merge")
+		fmt.Println("  $ gh fork-sync --shell")
 	}
 
 	flag.Parse()
 	return config
 }
 
-// GetOriginRepo returns the owner and repo name of the "origin" remote.
-func GetOriginRepo() (owner, repo string, err error) {
-	// Get the origin remote URL
-	cmd := exec.Command("git", "remote", "get-url", "origin")
-	output, err := cmd.CombinedOutput()
-	if err != nil {
-		return "", "", fmt.Errorf("failed to get origin remote: %v", err)
-	}
-
-	url := strings.TrimSpace(string(output))
+// printDryRun prints commands that would be executed
+func printDryRun(config *AppConfig, parentCloneURL string) {
+	fmt.Println("Note: The following commands are examples. The actual upstream URL will be taken from your fork's parent repository.")
+	fmt.Println("Dry run mode - commands that would be executed:")
+	fmt.Printf("Would run: git remote add %s %s\n", config.RemoteName, parentCloneURL)
+	fmt.Printf("Would run: git fetch %s\n", config.RemoteName)
 
-	// Parse SSH or HTTPS URL
-	var parts []string
-	if strings.HasPrefix(url, "git@github.com:") {
-		// SSH format: git@github.com:owner/repo.git
-		path := strings.TrimPrefix(url, "git@github.com:")
-		parts = strings.SplitN(path, "/", 2)
-	} else if strings.Contains(url, "github.com/") {
-		// HTTPS format: https://github.com/owner/repo.git
-		path := strings.SplitN(url, "github.com/", 2)[1]
-		parts = strings.SplitN(path, "/", 2)
-	} else {
-		return "", "", fmt.Errorf("unsupported origin URL format: %s", url)
+	if config.LFS {
+		fmt.Printf("Would run: git lfs fetch %s --all\n", config.RemoteName)
 	}
 
-	if len(parts) < 2 {
-		return "", "", fmt.Errorf("failed to parse owner/repo from URL: %s", url)
+	if config.Rebase {
+		fmt.Printf("Would run: git rebase %s/%s\n", config.RemoteName, config.UpstreamBranch)
+	} else {
+		fmt.Printf("Would run: git merge %s/%s\n", config.RemoteName, config.UpstreamBranch)
 	}
 
-	owner = parts[0]
-	repo = strings.TrimSuffix(parts[1], ".git") // Remove .git suffix if present
-	return owner, repo, nil
-}
-
-// getRepoInfo fetches repository information from GitHub API
-func getRepoInfo(client *api.RESTClient, owner, repoName string) (*RepoInfo, error) {
-	info := &RepoInfo{}
-	err := client.Get(fmt.Sprintf("repos/%s/%s", owner, repoName), info)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get repo info: %v", err)
+	if config.LFS {
+		fmt.Printf("Would run: git lfs push origin %s\n", config.OriginBranch)
 	}
-	return info, nil
-}
 
-// validateFork checks if the repository is a fork
-func validateFork(info *RepoInfo) error {
-	if !info.Fork {
-		return fmt.Errorf("repository %s isn't a fork", info.FullName)
+	pushCmd := "push"
+	if config.ForcePush {
+		pushCmd += " -f"
 	}
-	return nil
+	fmt.Printf("Would run: git %s origin HEAD:%s\n", pushCmd, config.OriginBranch)
 }
 
-// runGitCommand executes a git command and returns any error
-func runGitCommand(gitBin string, cmd GitCommand) error {
-	output, err := exec.Command(gitBin, cmd.Args...).CombinedOutput()
-	if err != nil {
-		return fmt.Errorf("%s: %v\nOutput: %s", cmd.Description, err, output)
+// toSyncOptions translates the CLI's AppConfig into a forksync.Options for a
+// single repository at dir.
+func toSyncOptions(config *AppConfig, dir string) forksync.Options {
+	return forksync.Options{
+		Dir:            dir,
+		Host:           config.Host,
+		HostType:       config.HostType,
+		RemoteName:     config.RemoteName,
+		ReplaceRemote:  config.ReplaceRemote,
+		UpstreamBranch: config.UpstreamBranch,
+		OriginBranch:   config.OriginBranch,
+		Rebase:         config.Rebase,
+		ForcePush:      config.ForcePush,
+		LFS:            config.LFS,
+		OnConflict:     config.OnConflict,
+		Shell:          config.Shell,
 	}
-	return nil
 }
 
-// getSyncCommand returns the appropriate sync command (merge or rebase)
-func getSyncCommand(config *AppConfig, upstreamBranch string) GitCommand {
-	if config.Rebase {
-		args := []string{"rebase", "upstream"}
-		if upstreamBranch != "" {
-			args = append(args, fmt.Sprintf("upstream/%s", upstreamBranch))
-		}
-		return GitCommand{
-			Args:        args,
-			Description: "rebasing onto upstream",
+// runSync runs forksync.Sync for a single repository, printing its progress
+// events as they arrive prefixed with label (so batch runs can tell entries
+// apart).
+func runSync(config *AppConfig, dir, label string) error {
+	events := make(chan forksync.Event, 16)
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for e := range events {
+			symbol := "✓"
+			if e.Level == forksync.EventWarning {
+				symbol = "!"
+			}
+			fmt.Printf("%s%s %s\n", label, symbol, e.Message)
 		}
-	}
-	args := []string{"merge", "upstream"}
-	if upstreamBranch != "" {
-		args = append(args, fmt.Sprintf("upstream/%s", upstreamBranch))
-	}
-	return GitCommand{
-		Args:        args,
-		Description: "merging upstream",
-	}
-}
-
-// printDryRun prints commands that would be executed
-func printDryRun(config *AppConfig, parentCloneURL string) {
-	fmt.Println("Note: The following commands are examples. The actual upstream URL will be taken from your fork's parent repository.")
-	fmt.Println("Dry run mode - commands that would be executed:")
-	fmt.Printf("Would run: git remote add upstream %s\n", parentCloneURL)
-	fmt.Printf("Would run: git fetch upstream\n")
+	}()
 
-	if config.Rebase {
-		fmt.Printf("Would run: git rebase upstream/%s\n", config.UpstreamBranch)
-	} else {
-		fmt.Printf("Would run: git merge upstream/%s\n", config.UpstreamBranch)
-	}
-
-	pushCmd := "push"
-	if config.ForcePush {
-		pushCmd += " -f"
-	}
-	fmt.Printf("Would run: git %s origin HEAD:%s\n", pushCmd, config.OriginBranch)
+	_, err := forksync.Sync(context.Background(), toSyncOptions(config, dir), events)
+	close(events)
+	wg.Wait()
+	return err
 }
 
 func main() {
@@ -182,97 +174,28 @@ func main() {
 		return
 	}
 
-	// Initialize the GitHub API client
-	client, err := api.DefaultRESTClient()
-	if err != nil {
-		fmt.Printf("✗ Error: %v\n", err)
-		return
-	}
-
-	// Get repository information
-	owner, repoName, err := GetOriginRepo()
-	if err != nil {
-		fmt.Printf("✗ Error: %v\n", err)
-		return
-	}
-
-	// Get and validate repository information
-	repoInfo, err := getRepoInfo(client, owner, repoName)
-	if err != nil {
-		fmt.Printf("✗ Error: %v\n", err)
-		return
-	}
-
-	if err := validateFork(repoInfo); err != nil {
-		fmt.Printf("✗ %v\n", err)
-		return
-	}
-
-	fmt.Printf("✓ Detected fork: %s (parent: %s)\n", repoInfo.FullName, repoInfo.Parent.FullName)
-
-	// Find git executable
-	gitBin, err := safeexec.LookPath("git")
-	if err != nil {
-		fmt.Printf("✗ Error while looking for git: %v\n", err)
-		return
-	}
-
-	// Add upstream remote
-	cmd := GitCommand{
-		Args:        []string{"remote", "add", "upstream", repoInfo.Parent.CloneURL},
-		Description: "adding upstream remote",
-	}
-	if err := runGitCommand(gitBin, cmd); err != nil {
-		if !strings.Contains(err.Error(), "remote upstream already exists") {
-			fmt.Printf("✗ Error while %v\n", err)
-			return
+	if config.ConfigFile != "" {
+		manifest, err := loadManifest(config.ConfigFile)
+		if err != nil {
+			fmt.Printf("✗ Error: %v\n", err)
+			os.Exit(1)
 		}
-	}
-
-	// Fetch upstream
-	cmd = GitCommand{
-		Args:        []string{"fetch", "upstream"},
-		Description: "fetching upstream",
-	}
-	if err := runGitCommand(gitBin, cmd); err != nil {
-		fmt.Printf("✗ Error while %v\n", err)
-		return
-	}
-	fmt.Println("✓ Fetched upstream")
-
-	// Sync with upstream
-	cmd = getSyncCommand(config, config.UpstreamBranch)
-	if err := runGitCommand(gitBin, cmd); err != nil {
-		fmt.Printf("✗ Error while %v\n", err)
-		if config.Rebase {
-			fmt.Println("To abort the rebase, run: git rebase --abort")
-		} else {
-			fmt.Println("To abort the merge, run: git merge --abort")
+		if runManifest(config, manifest) {
+			os.Exit(1)
 		}
 		return
 	}
-	if config.Rebase {
-		fmt.Printf("✓ Rebased onto upstream/%s\n", config.UpstreamBranch)
-	} else {
-		fmt.Printf("✓ Merged upstream/%s\n", config.UpstreamBranch)
-	}
 
-	// Push changes
-	pushArgs := []string{"push"}
-	if config.ForcePush {
-		pushArgs = append(pushArgs, "-f")
-	}
-	pushArgs = append(pushArgs, "origin", fmt.Sprintf("HEAD:%s", config.OriginBranch))
-
-	cmd = GitCommand{
-		Args:        pushArgs,
-		Description: fmt.Sprintf("pushing to origin/%s", config.OriginBranch),
-	}
-	if err := runGitCommand(gitBin, cmd); err != nil {
+	if err := runSync(config, "", ""); err != nil {
 		fmt.Printf("✗ Error while %v\n", err)
-		return
+		var gitErr *forksync.GitError
+		if errors.As(err, &gitErr) {
+			if hint := forksync.Remediation(gitErr); hint != "" {
+				fmt.Printf("  %s\n", hint)
+			}
+		}
+		os.Exit(1)
 	}
-	fmt.Printf("✓ Pushed to origin/%s\n", config.OriginBranch)
 }
 
 // For more examples of using go-gh, see:
@@ -3,7 +3,6 @@ package main
 import (
 	"flag"
 	"os"
-	"os/exec"
 	"testing"
 )
 
@@ -26,6 +25,11 @@ func TestParseFlags(t *testing.T) {
 				Rebase:         false,
 				ForcePush:      false,
 				DryRun:         false,
+				Parallel:       1,
+				OnConflict:     "abort",
+				Host:           "github.com",
+				HostType:       "github",
+				RemoteName:     "upstream",
 			},
 		},
 		{
@@ -37,6 +41,11 @@ func TestParseFlags(t *testing.T) {
 				Rebase:         false,
 				ForcePush:      false,
 				DryRun:         false,
+				Parallel:       1,
+				OnConflict:     "abort",
+				Host:           "github.com",
+				HostType:       "github",
+				RemoteName:     "upstream",
 			},
 		},
 		{
@@ -48,6 +57,81 @@ func TestParseFlags(t *testing.T) {
 				Rebase:         true,
 				ForcePush:      true,
 				DryRun:         true,
+				Parallel:       1,
+				OnConflict:     "abort",
+				Host:           "github.com",
+				HostType:       "github",
+				RemoteName:     "upstream",
+			},
+		},
+		{
+			name: "config and parallel",
+			args: []string{"gh-fork-sync", "--config=forks.yml", "--parallel=4"},
+			expected: &AppConfig{
+				UpstreamBranch: "main",
+				OriginBranch:   "main",
+				ConfigFile:     "forks.yml",
+				Parallel:       4,
+				OnConflict:     "abort",
+				Host:           "github.com",
+				HostType:       "github",
+				RemoteName:     "upstream",
+			},
+		},
+		{
+			name: "lfs enabled",
+			args: []string{"gh-fork-sync", "--lfs"},
+			expected: &AppConfig{
+				UpstreamBranch: "main",
+				OriginBranch:   "main",
+				Parallel:       1,
+				LFS:            true,
+				OnConflict:     "abort",
+				Host:           "github.com",
+				HostType:       "github",
+				RemoteName:     "upstream",
+			},
+		},
+		{
+			name: "on-conflict override",
+			args: []string{"gh-fork-sync", "--on-conflict=theirs"},
+			expected: &AppConfig{
+				UpstreamBranch: "main",
+				OriginBranch:   "main",
+				Parallel:       1,
+				OnConflict:     "theirs",
+				Host:           "github.com",
+				HostType:       "github",
+				RemoteName:     "upstream",
+			},
+		},
+		{
+			name: "custom remote name and replace",
+			args: []string{"gh-fork-sync", "--remote-name=parent", "--replace-remote"},
+			expected: &AppConfig{
+				UpstreamBranch: "main",
+				OriginBranch:   "main",
+				Parallel:       1,
+				OnConflict:     "abort",
+				Host:           "github.com",
+				HostType:       "github",
+				RemoteName:     "parent",
+				ReplaceRemote:  true,
+			},
+		},
+		{
+			name: "shell fallback",
+			args: []string{"gh-fork-sync", "--shell", "--rebase"},
+			expected: &AppConfig{
+				UpstreamBranch: "main",
+				OriginBranch:   "main",
+				Rebase:         true,
+				Parallel:       1,
+				OnConflict:     "abort",
+				Host:           "github.com",
+				HostType:       "github",
+				RemoteName:     "upstream",
+				Shell:          true,
 			},
 		},
 	}
@@ -76,172 +160,32 @@ func TestParseFlags(t *testing.T) {
 			if config.DryRun != tt.expected.DryRun {
 				t.Errorf("DryRun = %v, want %v", config.DryRun, tt.expected.DryRun)
 			}
-		})
-	}
-}
-
-func TestGetOriginRepo(t *testing.T) {
-	// Create temporary directory for test git repository
-	tmpDir, err := os.MkdirTemp("", "test-repo-*")
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.RemoveAll(tmpDir)
-
-	// Change to temporary directory
-	originalDir, err := os.Getwd()
-	if err != nil {
-		t.Fatal(err)
-	}
-	defer os.Chdir(originalDir)
-
-	if err := os.Chdir(tmpDir); err != nil {
-		t.Fatal(err)
-	}
-
-	// Initialize git repository
-	if err := exec.Command("git", "init").Run(); err != nil {
-		t.Fatal(err)
-	}
-
-	tests := []struct {
-		name          string
-		remoteURL     string
-		expectedOwner string
-		expectedRepo  string
-		wantErr       bool
-	}{
-		{
-			name:          "HTTPS URL",
-			remoteURL:     "https://github.com/owner/repo.git",
-			expectedOwner: "owner",
-			expectedRepo:  "repo",
-			wantErr:       false,
-		},
-		{
-			name:          "SSH URL",
-			remoteURL:     "git@github.com:owner/repo.git",
-			expectedOwner: "owner",
-			expectedRepo:  "repo",
-			wantErr:       false,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			// Set remote URL
-			cmd := exec.Command("git", "remote", "remove", "origin")
-			cmd.Run() // Ignore error if origin doesn't exist
-
-			cmd = exec.Command("git", "remote", "add", "origin", tt.remoteURL)
-			if err := cmd.Run(); err != nil {
-				t.Fatal(err)
+			if config.ConfigFile != tt.expected.ConfigFile {
+				t.Errorf("ConfigFile = %v, want %v", config.ConfigFile, tt.expected.ConfigFile)
 			}
-
-			owner, repo, err := GetOriginRepo()
-			if (err != nil) != tt.wantErr {
-				t.Errorf("GetOriginRepo() error = %v, wantErr %v", err, tt.wantErr)
-				return
+			if config.LFS != tt.expected.LFS {
+				t.Errorf("LFS = %v, want %v", config.LFS, tt.expected.LFS)
 			}
-
-			if owner != tt.expectedOwner {
-				t.Errorf("GetOriginRepo() owner = %v, want %v", owner, tt.expectedOwner)
+			if config.OnConflict != tt.expected.OnConflict {
+				t.Errorf("OnConflict = %v, want %v", config.OnConflict, tt.expected.OnConflict)
 			}
-			if repo != tt.expectedRepo {
-				t.Errorf("GetOriginRepo() repo = %v, want %v", repo, tt.expectedRepo)
+			if config.Parallel != tt.expected.Parallel {
+				t.Errorf("Parallel = %v, want %v", config.Parallel, tt.expected.Parallel)
 			}
-		})
-	}
-}
-
-func TestValidateFork(t *testing.T) {
-	tests := []struct {
-		name    string
-		info    *RepoInfo
-		wantErr bool
-	}{
-		{
-			name: "valid fork",
-			info: &RepoInfo{
-				FullName: "user/repo",
-				Fork:     true,
-			},
-			wantErr: false,
-		},
-		{
-			name: "not a fork",
-			info: &RepoInfo{
-				FullName: "user/repo",
-				Fork:     false,
-			},
-			wantErr: true,
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := validateFork(tt.info)
-			if (err != nil) != tt.wantErr {
-				t.Errorf("validateFork() error = %v, wantErr %v", err, tt.wantErr)
+			if config.Host != tt.expected.Host {
+				t.Errorf("Host = %v, want %v", config.Host, tt.expected.Host)
 			}
-		})
-	}
-}
-
-func TestGetSyncCommand(t *testing.T) {
-	tests := []struct {
-		name           string
-		config         *AppConfig
-		upstreamBranch string
-		expectedArgs   []string
-	}{
-		{
-			name: "merge without branch",
-			config: &AppConfig{
-				Rebase: false,
-			},
-			upstreamBranch: "",
-			expectedArgs:   []string{"merge", "upstream"},
-		},
-		{
-			name: "merge with branch",
-			config: &AppConfig{
-				Rebase: false,
-			},
-			upstreamBranch: "main",
-			expectedArgs:   []string{"merge", "upstream", "upstream/main"},
-		},
-		{
-			name: "rebase without branch",
-			config: &AppConfig{
-				Rebase: true,
-			},
-			upstreamBranch: "",
-			expectedArgs:   []string{"rebase", "upstream"},
-		},
-		{
-			name: "rebase with branch",
-			config: &AppConfig{
-				Rebase: true,
-			},
-			upstreamBranch: "main",
-			expectedArgs:   []string{"rebase", "upstream", "upstream/main"},
-		},
-	}
-
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			cmd := getSyncCommand(tt.config, tt.upstreamBranch)
-
-			if len(cmd.Args) != len(tt.expectedArgs) {
-				t.Errorf("getSyncCommand() args length = %v, want %v", len(cmd.Args), len(tt.expectedArgs))
-				return
+			if config.HostType != tt.expected.HostType {
+				t.Errorf("HostType = %v, want %v", config.HostType, tt.expected.HostType)
 			}
-
-			for i, arg := range cmd.Args {
-				if arg != tt.expectedArgs[i] {
-					t.Errorf("getSyncCommand() arg[%d] = %v, want %v", i, arg, tt.expectedArgs[i])
-				}
+			if config.RemoteName != tt.expected.RemoteName {
+				t.Errorf("RemoteName = %v, want %v", config.RemoteName, tt.expected.RemoteName)
+			}
+			if config.ReplaceRemote != tt.expected.ReplaceRemote {
+				t.Errorf("ReplaceRemote = %v, want %v", config.ReplaceRemote, tt.expected.ReplaceRemote)
+			}
+			if config.Shell != tt.expected.Shell {
+				t.Errorf("Shell = %v, want %v", config.Shell, tt.expected.Shell)
 			}
 		})
 	}
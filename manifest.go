@@ -0,0 +1,60 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// BranchMapping describes a single upstream/origin branch pair to sync for a manifest entry.
+type BranchMapping struct {
+	UpstreamBranch string `yaml:"upstream-branch"`
+	OriginBranch   string `yaml:"origin-branch"`
+	Rebase         bool   `yaml:"rebase"`
+	Force          bool   `yaml:"force"`
+}
+
+// ManifestEntry describes one fork to sync as part of a batch run.
+//
+// Per-entry credential overrides aren't implemented: every entry
+// authenticates the same way a single-repo sync does, via the ambient
+// gh/git credential helper, GH_TOKEN/GITHUB_TOKEN, or an ssh-agent.
+type ManifestEntry struct {
+	Repo       string          `yaml:"repo"`        // owner/name (cloned to a temp dir), or a path to a local clone
+	RemoteName string          `yaml:"remote-name"` // defaults to "upstream"
+	Branches   []BranchMapping `yaml:"branches"`
+}
+
+// Manifest is the top-level shape of a --config file: a list of forks to sync in one run.
+type Manifest struct {
+	Entries []ManifestEntry `yaml:"entries"`
+}
+
+// loadManifest reads and parses a YAML manifest file describing a batch of forks to sync.
+func loadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %v", err)
+	}
+
+	var manifest Manifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %v", err)
+	}
+
+	if len(manifest.Entries) == 0 {
+		return nil, fmt.Errorf("config file %s declares no entries", path)
+	}
+
+	for i, entry := range manifest.Entries {
+		if entry.Repo == "" {
+			return nil, fmt.Errorf("entry %d is missing a repo", i)
+		}
+		if len(entry.Branches) == 0 {
+			return nil, fmt.Errorf("entry %d (%s) declares no branches", i, entry.Repo)
+		}
+	}
+
+	return &manifest, nil
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadManifest(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	validPath := filepath.Join(tmpDir, "valid.yml")
+	validYAML := `
+entries:
+  - repo: owner/repo
+    remote-name: parent
+    branches:
+      - upstream-branch: main
+        origin-branch: main
+        rebase: true
+`
+	if err := os.WriteFile(validPath, []byte(validYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	emptyPath := filepath.Join(tmpDir, "empty.yml")
+	if err := os.WriteFile(emptyPath, []byte("entries: []"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	missingBranchesPath := filepath.Join(tmpDir, "missing-branches.yml")
+	if err := os.WriteFile(missingBranchesPath, []byte("entries:\n  - repo: owner/repo\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{name: "valid manifest", path: validPath, wantErr: false},
+		{name: "no entries", path: emptyPath, wantErr: true},
+		{name: "entry without branches", path: missingBranchesPath, wantErr: true},
+		{name: "missing file", path: filepath.Join(tmpDir, "nope.yml"), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manifest, err := loadManifest(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("loadManifest() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr {
+				if len(manifest.Entries) != 1 {
+					t.Fatalf("Entries length = %v, want 1", len(manifest.Entries))
+				}
+				entry := manifest.Entries[0]
+				if entry.Repo != "owner/repo" || entry.RemoteName != "parent" {
+					t.Errorf("unexpected entry: %+v", entry)
+				}
+				if len(entry.Branches) != 1 || entry.Branches[0].UpstreamBranch != "main" {
+					t.Errorf("unexpected branches: %+v", entry.Branches)
+				}
+			}
+		})
+	}
+}
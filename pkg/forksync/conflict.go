@@ -0,0 +1,200 @@
+package forksync
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ConflictResolution records which side a conflicted path was resolved from.
+type ConflictResolution struct {
+	Path string
+	Kept string // "ours", "theirs", or "skipped"
+}
+
+// parseConflictedPaths parses `git status --porcelain=v2` output and returns the
+// paths that still have unresolved merge conflicts (porcelain v2 marks these "u ").
+func parseConflictedPaths(porcelain string) []string {
+	var paths []string
+	for _, line := range strings.Split(porcelain, "\n") {
+		if !strings.HasPrefix(line, "u ") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 0 {
+			paths = append(paths, fields[len(fields)-1])
+		}
+	}
+	return paths
+}
+
+// resolveConflicts runs after a failed merge/rebase, handling any leftover conflicts
+// according to onConflict. For rebases it loops through `git rebase --continue`
+// until every commit has landed or the caller aborts. It returns a summary of which
+// side each conflicted path was kept from.
+func resolveConflicts(gitBin, dir, onConflict string, rebase bool, syncErr error) ([]ConflictResolution, error) {
+	var resolutions []ConflictResolution
+
+	for {
+		statusCmd := exec.Command(gitBin, "status", "--porcelain=v2")
+		statusCmd.Dir = dir
+		statusOut, err := statusCmd.Output()
+		if err != nil {
+			return resolutions, syncErr
+		}
+		paths := parseConflictedPaths(string(statusOut))
+		if len(paths) == 0 {
+			// Nothing conflicted on the first pass means the merge/rebase
+			// failed for another reason (unrelated histories, local changes
+			// in the way, ...), so report that original failure.
+			if len(resolutions) == 0 {
+				return resolutions, syncErr
+			}
+			// A rebase --continue can fail for reasons other than a fresh
+			// conflict (e.g. a commit became empty), leaving no "u " paths in
+			// status but the rebase still in progress and HEAD detached.
+			// Treat that as a failure rather than falling through as success.
+			if rebase && rebaseInProgress(gitBin, dir) {
+				return resolutions, fmt.Errorf("rebase is still in progress after 'rebase --continue' reported no conflicts: %w", syncErr)
+			}
+			break
+		}
+
+		switch onConflict {
+		case "abort", "":
+			abortConflict(gitBin, dir, rebase)
+			return resolutions, syncErr
+
+		case "ours", "theirs":
+			if err := resolveWithSide(gitBin, dir, onConflict, rebase, paths, &resolutions); err != nil {
+				return resolutions, err
+			}
+
+		case "skip":
+			if !rebase {
+				return resolutions, fmt.Errorf("--on-conflict=skip only applies with --rebase: %w", syncErr)
+			}
+			if err := runGitCommand(gitBin, GitCommand{Args: []string{"rebase", "--skip"}, Description: "skipping conflicting commit", Dir: dir}); err != nil {
+				return resolutions, err
+			}
+			for _, path := range paths {
+				resolutions = append(resolutions, ConflictResolution{Path: path, Kept: "skipped"})
+			}
+			continue
+
+		case "prompt":
+			for _, path := range paths {
+				side, err := promptConflictChoice(path)
+				if err != nil {
+					return resolutions, err
+				}
+				if side == "abort" {
+					abortConflict(gitBin, dir, rebase)
+					return resolutions, syncErr
+				}
+				if err := resolveWithSide(gitBin, dir, side, rebase, []string{path}, &resolutions); err != nil {
+					return resolutions, err
+				}
+			}
+
+		default:
+			return resolutions, fmt.Errorf("unknown --on-conflict value %q: %w", onConflict, syncErr)
+		}
+
+		if rebase {
+			if err := runGitCommand(gitBin, GitCommand{Args: []string{"rebase", "--continue"}, Description: "continuing rebase", Dir: dir}); err != nil {
+				continue // likely another conflicting commit further down the stack
+			}
+		} else {
+			if err := runGitCommand(gitBin, GitCommand{Args: []string{"commit", "--no-edit"}, Description: "completing merge", Dir: dir}); err != nil {
+				return resolutions, err
+			}
+			break
+		}
+	}
+
+	return resolutions, nil
+}
+
+// rebaseInProgress reports whether dir has a rebase underway, checking both
+// state directories git uses (rebase-merge for interactive/merge-based
+// rebases, rebase-apply for the am-based ones) via their canonical paths.
+func rebaseInProgress(gitBin, dir string) bool {
+	for _, state := range []string{"rebase-merge", "rebase-apply"} {
+		cmd := exec.Command(gitBin, "rev-parse", "--git-path", state)
+		cmd.Dir = dir
+		out, err := cmd.Output()
+		if err != nil {
+			continue
+		}
+		path := strings.TrimSpace(string(out))
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveWithSide checks out the given side for each path, stages it, and records the resolution.
+// side is always the user-facing meaning ("ours" = the fork's own changes,
+// "theirs" = upstream's) - git's own --ours/--theirs flip meaning during a
+// rebase (HEAD is temporarily upstream's commit, not the fork's), so rebase
+// swaps which git flag is actually passed to keep that meaning consistent.
+func resolveWithSide(gitBin, dir, side string, rebase bool, paths []string, resolutions *[]ConflictResolution) error {
+	gitSide := side
+	if rebase {
+		switch side {
+		case "ours":
+			gitSide = "theirs"
+		case "theirs":
+			gitSide = "ours"
+		}
+	}
+	for _, path := range paths {
+		if err := runGitCommand(gitBin, GitCommand{Args: []string{"checkout", "--" + gitSide, "--", path}, Description: "resolving " + path, Dir: dir}); err != nil {
+			return err
+		}
+		if err := runGitCommand(gitBin, GitCommand{Args: []string{"add", path}, Description: "staging " + path, Dir: dir}); err != nil {
+			return err
+		}
+		*resolutions = append(*resolutions, ConflictResolution{Path: path, Kept: side})
+	}
+	return nil
+}
+
+// abortConflict aborts the in-progress merge or rebase.
+func abortConflict(gitBin, dir string, rebase bool) {
+	if rebase {
+		runGitCommand(gitBin, GitCommand{Args: []string{"rebase", "--abort"}, Description: "aborting rebase", Dir: dir})
+	} else {
+		runGitCommand(gitBin, GitCommand{Args: []string{"merge", "--abort"}, Description: "aborting merge", Dir: dir})
+	}
+}
+
+// promptConflictChoice asks the user, via stdin, how to resolve a single conflicted path.
+func promptConflictChoice(path string) (string, error) {
+	fmt.Printf("Conflict in %s - keep [o]urs, [t]heirs, or [a]bort? ", path)
+	reader := bufio.NewReader(os.Stdin)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return "", fmt.Errorf("reading conflict choice: %v", err)
+		}
+		switch strings.TrimSpace(strings.ToLower(line)) {
+		case "o", "ours":
+			return "ours", nil
+		case "t", "theirs":
+			return "theirs", nil
+		case "a", "abort":
+			return "abort", nil
+		default:
+			fmt.Print("Please enter 'o', 't', or 'a': ")
+		}
+	}
+}
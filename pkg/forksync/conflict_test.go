@@ -0,0 +1,153 @@
+package forksync
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// rebaseConflictRepo sets up a repo where rebasing "feature" onto "main"
+// conflicts on f.txt: main and feature each changed it differently from
+// their shared base. It leaves "feature" checked out with the rebase
+// already started (and conflicted).
+func rebaseConflictRepo(t *testing.T, gitBin string) (dir string, rebaseErr error) {
+	t.Helper()
+	dir = t.TempDir()
+	runGit(t, dir, "init")
+	runGit(t, dir, "symbolic-ref", "HEAD", "refs/heads/main")
+
+	writeFile := func(content string) {
+		if err := os.WriteFile(filepath.Join(dir, "f.txt"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	writeFile("base\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "base")
+
+	runGit(t, dir, "checkout", "-b", "feature")
+	writeFile("feature\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "feature change")
+
+	runGit(t, dir, "checkout", "main")
+	writeFile("main\n")
+	runGit(t, dir, "add", ".")
+	runGit(t, dir, "commit", "-m", "main change")
+
+	runGit(t, dir, "checkout", "feature")
+
+	cmd := exec.Command(gitBin, "rebase", "main")
+	cmd.Dir = dir
+	rebaseErr = cmd.Run()
+	if rebaseErr == nil {
+		t.Fatal("expected git rebase main to conflict, it succeeded")
+	}
+	return dir, rebaseErr
+}
+
+func TestParseConflictedPaths(t *testing.T) {
+	tests := []struct {
+		name      string
+		porcelain string
+		expected  []string
+	}{
+		{
+			name:      "no conflicts",
+			porcelain: "1 M. N... 100644 100644 100644 abcd1234 abcd1234 file.txt\n",
+			expected:  nil,
+		},
+		{
+			name:      "single conflict",
+			porcelain: "u UU N... 100644 100644 100644 100644 abcd1234 abcd1234 abcd1234 conflict.go\n",
+			expected:  []string{"conflict.go"},
+		},
+		{
+			name: "multiple conflicts",
+			porcelain: "u UU N... 100644 100644 100644 100644 abcd1234 abcd1234 abcd1234 a.go\n" +
+				"1 M. N... 100644 100644 100644 abcd1234 abcd1234 clean.go\n" +
+				"u AA N... 100644 100644 100644 100644 abcd1234 abcd1234 abcd1234 b.go\n",
+			expected: []string{"a.go", "b.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := parseConflictedPaths(tt.porcelain)
+			if !reflect.DeepEqual(paths, tt.expected) {
+				t.Errorf("parseConflictedPaths() = %v, want %v", paths, tt.expected)
+			}
+		})
+	}
+}
+
+func TestRebaseInProgress(t *testing.T) {
+	gitBin, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+
+	dir := t.TempDir()
+	if err := exec.Command(gitBin, "init", dir).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	if rebaseInProgress(gitBin, dir) {
+		t.Error("rebaseInProgress() = true before any rebase started")
+	}
+
+	if err := os.Mkdir(filepath.Join(dir, ".git", "rebase-merge"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if !rebaseInProgress(gitBin, dir) {
+		t.Error("rebaseInProgress() = false with rebase-merge present")
+	}
+}
+
+// TestResolveConflictsRebaseSideMeaning guards against git's --ours/--theirs
+// meaning flipping during a rebase: --on-conflict=theirs must still keep
+// upstream's version, and --on-conflict=ours the fork's own, regardless of
+// sync mode.
+func TestResolveConflictsRebaseSideMeaning(t *testing.T) {
+	gitBin, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+
+	tests := []struct {
+		onConflict  string
+		wantContent string
+	}{
+		{onConflict: "theirs", wantContent: "main\n"},
+		{onConflict: "ours", wantContent: "feature\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.onConflict, func(t *testing.T) {
+			// rebase --continue creates a real commit whenever the resolved
+			// content differs from what was already there, which needs a
+			// configured git identity.
+			t.Setenv("GIT_AUTHOR_NAME", "test")
+			t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+			t.Setenv("GIT_COMMITTER_NAME", "test")
+			t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+			dir, rebaseErr := rebaseConflictRepo(t, gitBin)
+
+			if _, err := resolveConflicts(gitBin, dir, tt.onConflict, true, rebaseErr); err != nil {
+				t.Fatalf("resolveConflicts() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "f.txt"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != tt.wantContent {
+				t.Errorf("f.txt = %q, want %q", data, tt.wantContent)
+			}
+		})
+	}
+}
@@ -0,0 +1,318 @@
+// Package forksync implements the sync-a-fork-with-its-upstream logic behind
+// the gh fork-sync CLI, as an importable library.
+//
+// By default Sync drives go-git in-process, which makes it cancellable via
+// context.Context, lets callers watch progress on a chan Event, and fetches,
+// fast-forwards, and pushes without a git binary (reading the origin remote's
+// URL still runs `git remote get-url origin`). go-git has no rebase support and no general three-way
+// merge, though, so Options.Shell (or Options.Rebase) falls back to shelling
+// out to the git binary via safeexec, the way earlier versions of this
+// package always did.
+package forksync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cli/safeexec"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	gitssh "github.com/go-git/go-git/v5/plumbing/transport/ssh"
+)
+
+// EventLevel distinguishes routine progress from a warning worth calling out
+// to the user.
+type EventLevel string
+
+const (
+	EventInfo    EventLevel = "info"
+	EventWarning EventLevel = "warning"
+)
+
+// Event is a progress notification emitted while a Sync call runs.
+type Event struct {
+	Phase   string
+	Message string
+	Level   EventLevel // defaults to EventInfo; see EventWarning
+}
+
+// Options configures a single Sync call.
+type Options struct {
+	Dir  string // repository to sync ("" for the current directory)
+	Host string // git host to talk to, e.g. github.com or gitlab.example.com
+	// HostType is the git hosting provider type: github, gitlab, gitea, bitbucket.
+	HostType string
+
+	RemoteName    string // name of the git remote to point at the fork's parent
+	ReplaceRemote bool   // update RemoteName's URL if it already exists and points elsewhere
+
+	UpstreamBranch string
+	OriginBranch   string
+	Rebase         bool
+	ForcePush      bool
+
+	LFS        bool   // also sync Git LFS objects
+	OnConflict string // how to handle merge/rebase conflicts: abort, skip, theirs, ours, prompt
+
+	// Shell forces every step through the git binary instead of go-git, even
+	// for operations go-git could otherwise do natively.
+	Shell bool
+}
+
+// Report summarizes what a Sync call did.
+type Report struct {
+	RepoFullName   string
+	ParentFullName string
+	Resolutions    []ConflictResolution
+}
+
+// Sync detects opts.Dir's fork, syncs it with its parent, and pushes the
+// result, emitting progress on events (which may be nil).
+func Sync(ctx context.Context, opts Options, events chan<- Event) (Report, error) {
+	emit := func(phase, message string) {
+		if events != nil {
+			events <- Event{Phase: phase, Message: message, Level: EventInfo}
+		}
+	}
+	warn := func(phase, message string) {
+		if events != nil {
+			events <- Event{Phase: phase, Message: message, Level: EventWarning}
+		}
+	}
+
+	resolver, err := newForkResolver(opts.Host, opts.HostType)
+	if err != nil {
+		return Report{}, err
+	}
+
+	owner, repoName, err := GetOriginRepo(opts.Dir, NewRemoteParser(opts.Host))
+	if err != nil {
+		return Report{}, err
+	}
+
+	repoInfo, err := resolver.ResolveFork(owner, repoName)
+	if err != nil {
+		return Report{}, err
+	}
+	if err := validateFork(repoInfo); err != nil {
+		return Report{}, err
+	}
+	emit("detect", fmt.Sprintf("detected fork: %s (parent: %s)", repoInfo.FullName, repoInfo.Parent.FullName))
+
+	report := Report{RepoFullName: repoInfo.FullName, ParentFullName: repoInfo.Parent.FullName}
+
+	if opts.LFS {
+		if err := checkLFSInstalled(); err != nil {
+			return report, err
+		}
+	}
+
+	// go-git has no LFS or rebase support and no general three-way merge, so
+	// those cases always go through the shell fallback; --shell forces it too.
+	if opts.Shell || opts.Rebase || opts.LFS {
+		resolutions, err := syncShell(opts, repoInfo, emit, warn)
+		report.Resolutions = resolutions
+		return report, err
+	}
+
+	resolutions, err := syncGoGit(ctx, opts, repoInfo, emit)
+	report.Resolutions = resolutions
+	return report, err
+}
+
+// syncGoGit implements Sync's default path: fetch, fast-forward merge, and
+// push, all performed in-process via go-git.
+func syncGoGit(ctx context.Context, opts Options, repoInfo *RepoInfo, emit func(phase, message string)) ([]ConflictResolution, error) {
+	repo, err := git.PlainOpen(opts.Dir)
+	if err != nil {
+		return nil, fmt.Errorf("opening repository: %v", err)
+	}
+
+	if err := ensureGoGitRemote(repo, opts.RemoteName, repoInfo.Parent.CloneURL, opts.ReplaceRemote); err != nil {
+		return nil, err
+	}
+
+	auth, err := resolveAuth(repoInfo.Parent.CloneURL)
+	if err != nil {
+		return nil, err
+	}
+
+	emit("fetch", fmt.Sprintf("fetching %s", opts.RemoteName))
+	err = repo.FetchContext(ctx, &git.FetchOptions{RemoteName: opts.RemoteName, Auth: auth})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, fmt.Errorf("fetching %s: %v", opts.RemoteName, err)
+	}
+	emit("fetch", fmt.Sprintf("fetched %s", opts.RemoteName))
+
+	upstreamRef, err := repo.Reference(plumbing.NewRemoteReferenceName(opts.RemoteName, opts.UpstreamBranch), true)
+	if err != nil {
+		return nil, fmt.Errorf("resolving %s/%s: %v", opts.RemoteName, opts.UpstreamBranch, err)
+	}
+
+	head, err := repo.Head()
+	if err != nil {
+		return nil, fmt.Errorf("resolving HEAD: %v", err)
+	}
+
+	ff, err := isAncestor(repo, head.Hash(), upstreamRef.Hash())
+	if err != nil {
+		return nil, fmt.Errorf("checking merge ancestry: %v", err)
+	}
+	if !ff {
+		return nil, fmt.Errorf("%s/%s isn't a fast-forward of HEAD; go-git only supports fast-forward merges, rerun with --shell (or --rebase) for a true merge", opts.RemoteName, opts.UpstreamBranch)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, fmt.Errorf("opening worktree: %v", err)
+	}
+
+	emit("sync", fmt.Sprintf("fast-forwarding to %s/%s", opts.RemoteName, opts.UpstreamBranch))
+	// Branch and Hash are mutually exclusive on CheckoutOptions, so advancing
+	// the current branch to upstreamRef's commit takes a hard reset instead of
+	// a checkout - HEAD is already on the branch we're fast-forwarding.
+	if err := wt.Reset(&git.ResetOptions{Commit: upstreamRef.Hash(), Mode: git.HardReset}); err != nil {
+		return nil, fmt.Errorf("fast-forwarding: %v", err)
+	}
+	emit("sync", fmt.Sprintf("fast-forwarded to %s/%s", opts.RemoteName, opts.UpstreamBranch))
+
+	emit("push", fmt.Sprintf("pushing to origin/%s", opts.OriginBranch))
+	rawRefSpec := fmt.Sprintf("HEAD:refs/heads/%s", opts.OriginBranch)
+	if opts.ForcePush {
+		rawRefSpec = "+" + rawRefSpec
+	}
+	err = repo.PushContext(ctx, &git.PushOptions{RemoteName: "origin", RefSpecs: []config.RefSpec{config.RefSpec(rawRefSpec)}, Auth: auth})
+	if err != nil && err != git.NoErrAlreadyUpToDate {
+		return nil, fmt.Errorf("pushing to origin/%s: %v", opts.OriginBranch, err)
+	}
+	emit("push", fmt.Sprintf("pushed to origin/%s", opts.OriginBranch))
+
+	return nil, nil
+}
+
+// isAncestor reports whether the commit at headHash is an ancestor of (or
+// equal to) the commit at upstreamHash - i.e. whether headHash can be
+// fast-forwarded to upstreamHash.
+func isAncestor(repo *git.Repository, headHash, upstreamHash plumbing.Hash) (bool, error) {
+	if headHash == upstreamHash {
+		return true, nil
+	}
+	headCommit, err := repo.CommitObject(headHash)
+	if err != nil {
+		return false, err
+	}
+	upstreamCommit, err := repo.CommitObject(upstreamHash)
+	if err != nil {
+		return false, err
+	}
+	return headCommit.IsAncestor(upstreamCommit)
+}
+
+// resolveAuth picks a go-git transport.AuthMethod for cloneURL: ssh-agent for
+// SSH remotes, or HTTP basic auth carrying the GH_TOKEN/GITHUB_TOKEN token as
+// the password for HTTPS ones
+// (nil lets go-git fall back to .netrc / a configured credential helper).
+func resolveAuth(cloneURL string) (transport.AuthMethod, error) {
+	if strings.HasPrefix(cloneURL, "git@") || strings.HasPrefix(cloneURL, "ssh://") {
+		auth, err := gitssh.NewSSHAgentAuth("git")
+		if err != nil {
+			return nil, fmt.Errorf("setting up ssh-agent auth: %v", err)
+		}
+		return auth, nil
+	}
+
+	if token := firstNonEmpty(os.Getenv("GH_TOKEN"), os.Getenv("GITHUB_TOKEN")); token != "" {
+		return &githttp.BasicAuth{Username: "x-access-token", Password: token}, nil
+	}
+	return nil, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+// syncShell implements Sync's fallback path: the original exec.Command-based
+// fetch/merge-or-rebase/push flow, used for rebases, LFS, and whenever the
+// caller sets Options.Shell.
+func syncShell(opts Options, repoInfo *RepoInfo, emit, warn func(phase, message string)) ([]ConflictResolution, error) {
+	if !usesLFS(opts.Dir) && opts.LFS {
+		warn("lfs", fmt.Sprintf("--lfs was set but %s has no LFS filters in .gitattributes; continuing anyway", repoInfo.FullName))
+	}
+
+	gitBin, err := safeexec.LookPath("git")
+	if err != nil {
+		return nil, fmt.Errorf("looking for git: %v", err)
+	}
+
+	if err := ensureRemote(gitBin, opts.Dir, opts.RemoteName, repoInfo.Parent.CloneURL, opts.ReplaceRemote); err != nil {
+		return nil, err
+	}
+
+	cmd := GitCommand{Args: []string{"fetch", opts.RemoteName}, Description: fmt.Sprintf("fetching %s", opts.RemoteName), Dir: opts.Dir}
+	if err := runGitCommand(gitBin, cmd); err != nil {
+		return nil, err
+	}
+	emit("fetch", fmt.Sprintf("fetched %s", opts.RemoteName))
+
+	if opts.LFS {
+		cmd = GitCommand{Args: []string{"lfs", "fetch", opts.RemoteName, "--all"}, Description: fmt.Sprintf("fetching %s LFS objects", opts.RemoteName), Dir: opts.Dir}
+		if err := runGitCommand(gitBin, cmd); err != nil {
+			return nil, err
+		}
+		emit("fetch", fmt.Sprintf("fetched %s LFS objects", opts.RemoteName))
+	}
+
+	var resolutions []ConflictResolution
+	cmd = getSyncCommand(opts.Rebase, opts.RemoteName, opts.UpstreamBranch)
+	cmd.Dir = opts.Dir
+	if err := runGitCommand(gitBin, cmd); err != nil {
+		var rerr error
+		resolutions, rerr = resolveConflicts(gitBin, opts.Dir, opts.OnConflict, opts.Rebase, err)
+		if rerr != nil {
+			if opts.Rebase {
+				return resolutions, fmt.Errorf("%w\nTo abort the rebase, run: git rebase --abort", rerr)
+			}
+			return resolutions, fmt.Errorf("%w\nTo abort the merge, run: git merge --abort", rerr)
+		}
+		for _, r := range resolutions {
+			emit("sync", fmt.Sprintf("kept %s from %s", r.Path, r.Kept))
+		}
+	}
+	if opts.Rebase {
+		emit("sync", fmt.Sprintf("rebased onto %s/%s", opts.RemoteName, opts.UpstreamBranch))
+	} else {
+		emit("sync", fmt.Sprintf("merged %s/%s", opts.RemoteName, opts.UpstreamBranch))
+	}
+
+	if opts.LFS {
+		cmd = GitCommand{Args: []string{"lfs", "push", "origin", opts.OriginBranch}, Description: fmt.Sprintf("pushing LFS objects to origin/%s", opts.OriginBranch), Dir: opts.Dir}
+		if err := runGitCommand(gitBin, cmd); err != nil {
+			return resolutions, err
+		}
+		emit("push", fmt.Sprintf("pushed LFS objects to origin/%s", opts.OriginBranch))
+	}
+
+	pushArgs := []string{"push"}
+	if opts.ForcePush {
+		pushArgs = append(pushArgs, "-f")
+	}
+	pushArgs = append(pushArgs, "origin", fmt.Sprintf("HEAD:%s", opts.OriginBranch))
+
+	cmd = GitCommand{Args: pushArgs, Description: fmt.Sprintf("pushing to origin/%s", opts.OriginBranch), Dir: opts.Dir}
+	if err := runGitCommand(gitBin, cmd); err != nil {
+		return resolutions, err
+	}
+	emit("push", fmt.Sprintf("pushed to origin/%s", opts.OriginBranch))
+
+	return resolutions, nil
+}
@@ -0,0 +1,85 @@
+package forksync
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// runGit runs a git command in dir (or the current directory if dir is ""),
+// failing the test if it errors.
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+// TestSyncGoGit exercises the default (non-shell) sync path end-to-end
+// against real repositories, fetching a fast-forward from "upstream" and
+// pushing the result to "origin".
+func TestSyncGoGit(t *testing.T) {
+	upstreamDir := t.TempDir()
+	runGit(t, upstreamDir, "init")
+	runGit(t, upstreamDir, "symbolic-ref", "HEAD", "refs/heads/main")
+	// Accept the push below even though main is checked out in upstreamDir.
+	runGit(t, upstreamDir, "config", "receive.denyCurrentBranch", "ignore")
+
+	if err := os.WriteFile(filepath.Join(upstreamDir, "README.md"), []byte("v1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, upstreamDir, "add", ".")
+	runGit(t, upstreamDir, "commit", "-m", "v1")
+
+	forkDir := filepath.Join(t.TempDir(), "fork")
+	runGit(t, "", "clone", upstreamDir, forkDir)
+	runGit(t, forkDir, "remote", "add", "upstream", upstreamDir)
+
+	// Advance upstream past what the fork cloned.
+	if err := os.WriteFile(filepath.Join(upstreamDir, "README.md"), []byte("v2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, upstreamDir, "add", ".")
+	runGit(t, upstreamDir, "commit", "-m", "v2")
+
+	opts := Options{
+		Dir:            forkDir,
+		RemoteName:     "upstream",
+		UpstreamBranch: "main",
+		OriginBranch:   "main",
+	}
+	repoInfo := &RepoInfo{FullName: "owner/fork"}
+	repoInfo.Parent.CloneURL = upstreamDir
+
+	events := make(chan Event, 16)
+	go func() {
+		for range events {
+		}
+	}()
+	resolutions, err := syncGoGit(context.Background(), opts, repoInfo, func(phase, message string) {
+		events <- Event{Phase: phase, Message: message}
+	})
+	close(events)
+	if err != nil {
+		t.Fatalf("syncGoGit() error = %v", err)
+	}
+	if resolutions != nil {
+		t.Errorf("resolutions = %v, want nil", resolutions)
+	}
+
+	data, err := os.ReadFile(filepath.Join(forkDir, "README.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "v2\n" {
+		t.Errorf("README.md = %q, want %q", data, "v2\n")
+	}
+}
@@ -0,0 +1,51 @@
+package forksync
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+)
+
+// GitCommand represents a git command to be executed via the --shell fallback.
+type GitCommand struct {
+	Args        []string
+	Description string
+	Dir         string // working directory to run the command in ("" for the current directory)
+}
+
+// runGitCommand executes a git command and returns any error
+func runGitCommand(gitBin string, cmd GitCommand) error {
+	execCmd := exec.Command(gitBin, cmd.Args...)
+	execCmd.Dir = cmd.Dir
+	execCmd.Env = append(os.Environ(),
+		fmt.Sprintf("LC_ALL=%s", gitLocale),
+		fmt.Sprintf("GIT_TERMINAL_PROMPT=%s", gitTerminalPrompt),
+	)
+	output, err := execCmd.CombinedOutput()
+	if err != nil {
+		return classifyGitError(cmd.Description, string(output))
+	}
+	return nil
+}
+
+// getSyncCommand returns the appropriate sync command (merge or rebase) against remoteName
+func getSyncCommand(rebase bool, remoteName, upstreamBranch string) GitCommand {
+	if rebase {
+		args := []string{"rebase", remoteName}
+		if upstreamBranch != "" {
+			args = append(args, fmt.Sprintf("%s/%s", remoteName, upstreamBranch))
+		}
+		return GitCommand{
+			Args:        args,
+			Description: fmt.Sprintf("rebasing onto %s", remoteName),
+		}
+	}
+	args := []string{"merge", remoteName}
+	if upstreamBranch != "" {
+		args = append(args, fmt.Sprintf("%s/%s", remoteName, upstreamBranch))
+	}
+	return GitCommand{
+		Args:        args,
+		Description: fmt.Sprintf("merging %s", remoteName),
+	}
+}
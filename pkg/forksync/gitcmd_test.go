@@ -0,0 +1,54 @@
+package forksync
+
+import "testing"
+
+func TestGetSyncCommand(t *testing.T) {
+	tests := []struct {
+		name           string
+		rebase         bool
+		upstreamBranch string
+		expectedArgs   []string
+	}{
+		{
+			name:           "merge without branch",
+			rebase:         false,
+			upstreamBranch: "",
+			expectedArgs:   []string{"merge", "upstream"},
+		},
+		{
+			name:           "merge with branch",
+			rebase:         false,
+			upstreamBranch: "main",
+			expectedArgs:   []string{"merge", "upstream", "upstream/main"},
+		},
+		{
+			name:           "rebase without branch",
+			rebase:         true,
+			upstreamBranch: "",
+			expectedArgs:   []string{"rebase", "upstream"},
+		},
+		{
+			name:           "rebase with branch",
+			rebase:         true,
+			upstreamBranch: "main",
+			expectedArgs:   []string{"rebase", "upstream", "upstream/main"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := getSyncCommand(tt.rebase, "upstream", tt.upstreamBranch)
+
+			if len(cmd.Args) != len(tt.expectedArgs) {
+				t.Errorf("getSyncCommand() args length = %v, want %v", len(cmd.Args), len(tt.expectedArgs))
+				return
+			}
+
+			for i, arg := range cmd.Args {
+				if arg != tt.expectedArgs[i] {
+					t.Errorf("getSyncCommand() arg[%d] = %v, want %v", i, arg, tt.expectedArgs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package forksync
+
+import (
+	"fmt"
+	"strings"
+)
+
+// gitLocale and gitTerminalPrompt pin the environment runGitCommand runs git
+// with, so its failure messages are in English (parseable by classifyGitError)
+// and it never blocks waiting on a credential prompt. The direct git calls
+// outside runGitCommand (remote get-url, status, rev-parse) don't set them.
+// Overridable at build time,
+// e.g. -ldflags "-X github.com/ankddev/gh-fork-sync/pkg/forksync.gitLocale=C.UTF-8".
+var (
+	gitLocale         = "C"
+	gitTerminalPrompt = "0"
+)
+
+// GitErrorKind classifies a failed git invocation by its English failure signature.
+type GitErrorKind string
+
+const (
+	GitErrorUnknown                 GitErrorKind = "unknown"
+	GitErrorMergeConflict           GitErrorKind = "merge_conflict"
+	GitErrorLocalChangesOverwritten GitErrorKind = "local_changes_overwritten"
+	GitErrorUnrelatedHistories      GitErrorKind = "unrelated_histories"
+	GitErrorUpdatesRejected         GitErrorKind = "updates_rejected"
+)
+
+// GitError is a classified git command failure.
+type GitError struct {
+	Kind        GitErrorKind
+	Paths       []string
+	Description string
+	Raw         string
+}
+
+func (e *GitError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Description, e.Raw)
+}
+
+// classifyGitError recognizes common English git failure signatures in output
+// and returns a GitError describing what went wrong.
+func classifyGitError(description, output string) *GitError {
+	gitErr := &GitError{Kind: GitErrorUnknown, Description: description, Raw: output}
+
+	switch {
+	case strings.Contains(output, "CONFLICT (content):") || strings.Contains(output, "Automatic merge failed"):
+		gitErr.Kind = GitErrorMergeConflict
+		gitErr.Paths = conflictPathsFromOutput(output)
+	case strings.Contains(output, "Your local changes to the following files would be overwritten"):
+		gitErr.Kind = GitErrorLocalChangesOverwritten
+		gitErr.Paths = indentedPathsAfter(output, "Your local changes to the following files would be overwritten")
+	case strings.Contains(output, "fatal: refusing to merge unrelated histories"):
+		gitErr.Kind = GitErrorUnrelatedHistories
+	case strings.Contains(output, "Updates were rejected because"):
+		gitErr.Kind = GitErrorUpdatesRejected
+	}
+
+	return gitErr
+}
+
+// conflictPathsFromOutput extracts paths from "CONFLICT (content): Merge conflict in <path>" lines.
+func conflictPathsFromOutput(output string) []string {
+	const marker = "Merge conflict in "
+	var paths []string
+	for _, line := range strings.Split(output, "\n") {
+		if idx := strings.Index(line, marker); idx != -1 {
+			paths = append(paths, strings.TrimSpace(line[idx+len(marker):]))
+		}
+	}
+	return paths
+}
+
+// indentedPathsAfter returns the tab-indented lines that follow a header line
+// containing marker - the shape git uses to list affected paths under a warning.
+func indentedPathsAfter(output, marker string) []string {
+	lines := strings.Split(output, "\n")
+	start := -1
+	for i, line := range lines {
+		if strings.Contains(line, marker) {
+			start = i + 1
+			break
+		}
+	}
+	if start == -1 {
+		return nil
+	}
+
+	var paths []string
+	for _, line := range lines[start:] {
+		if line == "" || !strings.HasPrefix(line, "\t") {
+			break
+		}
+		paths = append(paths, strings.TrimSpace(line))
+	}
+	return paths
+}
+
+// Remediation suggests a fix for a classified git failure.
+func Remediation(gitErr *GitError) string {
+	switch gitErr.Kind {
+	case GitErrorUnrelatedHistories:
+		return "The histories share no common commit; retry with a merge strategy that allows it (not currently exposed as a flag, but 'git merge --allow-unrelated-histories' works from the shell)."
+	case GitErrorUpdatesRejected:
+		return "origin has commits this push doesn't; use --force to overwrite them (only if that's really what you want)."
+	case GitErrorLocalChangesOverwritten:
+		if len(gitErr.Paths) > 0 {
+			return fmt.Sprintf("Commit, stash, or discard the changes to: %s", strings.Join(gitErr.Paths, ", "))
+		}
+		return "Commit, stash, or discard your local changes and try again."
+	case GitErrorMergeConflict:
+		return "Use --on-conflict to resolve automatically, or run 'git status' to resolve conflicts by hand."
+	default:
+		return ""
+	}
+}
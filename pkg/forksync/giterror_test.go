@@ -0,0 +1,59 @@
+package forksync
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassifyGitError(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		expectedKind  GitErrorKind
+		expectedPaths []string
+	}{
+		{
+			name:         "merge conflict",
+			output:       "Auto-merging a.go\nCONFLICT (content): Merge conflict in a.go\nAutomatic merge failed; fix conflicts and then commit the result.\n",
+			expectedKind: GitErrorMergeConflict,
+			expectedPaths: []string{
+				"a.go",
+			},
+		},
+		{
+			name: "local changes would be overwritten",
+			output: "error: Your local changes to the following files would be overwritten by merge:\n" +
+				"\ta.go\n\tb.go\n" +
+				"Please commit your changes or stash them before you merge.\n",
+			expectedKind:  GitErrorLocalChangesOverwritten,
+			expectedPaths: []string{"a.go", "b.go"},
+		},
+		{
+			name:         "unrelated histories",
+			output:       "fatal: refusing to merge unrelated histories\n",
+			expectedKind: GitErrorUnrelatedHistories,
+		},
+		{
+			name:         "rejected push",
+			output:       "! [rejected]        main -> main (fetch first)\nerror: failed to push some refs\nUpdates were rejected because the remote contains work that you do not have locally.\n",
+			expectedKind: GitErrorUpdatesRejected,
+		},
+		{
+			name:         "unrecognized failure",
+			output:       "fatal: something else went wrong\n",
+			expectedKind: GitErrorUnknown,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gitErr := classifyGitError("doing the thing", tt.output)
+			if gitErr.Kind != tt.expectedKind {
+				t.Errorf("Kind = %v, want %v", gitErr.Kind, tt.expectedKind)
+			}
+			if !reflect.DeepEqual(gitErr.Paths, tt.expectedPaths) {
+				t.Errorf("Paths = %v, want %v", gitErr.Paths, tt.expectedPaths)
+			}
+		})
+	}
+}
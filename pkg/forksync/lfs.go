@@ -0,0 +1,27 @@
+package forksync
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cli/safeexec"
+)
+
+// usesLFS reports whether dir's .gitattributes declares any Git LFS filters.
+func usesLFS(dir string) bool {
+	data, err := os.ReadFile(filepath.Join(dir, ".gitattributes"))
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), "filter=lfs")
+}
+
+// checkLFSInstalled returns an actionable error if the git-lfs binary isn't on PATH.
+func checkLFSInstalled() error {
+	if _, err := safeexec.LookPath("git-lfs"); err != nil {
+		return fmt.Errorf("--lfs was set but git-lfs isn't installed; install it from https://git-lfs.com and run 'git lfs install'")
+	}
+	return nil
+}
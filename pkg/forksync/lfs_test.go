@@ -0,0 +1,38 @@
+package forksync
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestUsesLFS(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	tests := []struct {
+		name       string
+		attributes string
+		write      bool
+		expected   bool
+	}{
+		{name: "no .gitattributes", write: false, expected: false},
+		{name: "no lfs filters", attributes: "*.txt text\n", write: true, expected: false},
+		{name: "lfs filter present", attributes: "*.psd filter=lfs diff=lfs merge=lfs -text\n", write: true, expected: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tmpDir, ".gitattributes")
+			os.Remove(path)
+			if tt.write {
+				if err := os.WriteFile(path, []byte(tt.attributes), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if got := usesLFS(tmpDir); got != tt.expected {
+				t.Errorf("usesLFS() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package forksync
+
+import (
+	"fmt"
+	"net/url"
+	"os/exec"
+	"strings"
+)
+
+// GetOriginRepo returns the owner and repo name of the "origin" remote, using
+// parser to interpret the remote URL for the configured git host.
+// dir is the repository to inspect ("" for the current directory).
+func GetOriginRepo(dir string, parser RemoteParser) (owner, repo string, err error) {
+	cmd := exec.Command("git", "remote", "get-url", "origin")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get origin remote: %v", err)
+	}
+
+	return parser.Parse(strings.TrimSpace(string(output)))
+}
+
+// RemoteParser extracts the owner and repo name encoded in a git remote URL for
+// a specific hosting provider.
+type RemoteParser interface {
+	Parse(rawURL string) (owner, repo string, err error)
+}
+
+// hostRemoteParser is a RemoteParser for any provider that addresses repos with
+// GitHub-style owner/repo paths - GitHub, GitLab, Gitea, and Bitbucket all do.
+type hostRemoteParser struct {
+	host string
+}
+
+// NewRemoteParser returns the RemoteParser for the given provider host.
+func NewRemoteParser(host string) RemoteParser {
+	return hostRemoteParser{host: host}
+}
+
+func (p hostRemoteParser) Parse(rawURL string) (owner, repo string, err error) {
+	raw := strings.TrimSpace(rawURL)
+
+	// SCP-style shorthand (git@host:owner/repo.git) isn't a URL - url.Parse will
+	// "succeed" on it but yield the wrong Host/Path, so it needs its own branch.
+	if path, ok := strings.CutPrefix(raw, fmt.Sprintf("git@%s:", p.host)); ok {
+		return splitOwnerRepo(path, raw)
+	}
+
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to parse remote URL: %v", err)
+	}
+
+	if u.Hostname() != p.host {
+		return "", "", fmt.Errorf("unsupported remote host %q (expected %s): %s", u.Hostname(), p.host, raw)
+	}
+
+	return splitOwnerRepo(strings.TrimPrefix(u.Path, "/"), raw)
+}
+
+// splitOwnerRepo splits an "owner/repo(.git)" path into its two parts.
+func splitOwnerRepo(path, rawURL string) (owner, repo string, err error) {
+	parts := strings.SplitN(strings.Trim(path, "/"), "/", 2)
+	if len(parts) < 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("failed to parse owner/repo from URL: %s", rawURL)
+	}
+	return parts[0], strings.TrimSuffix(parts[1], ".git"), nil
+}
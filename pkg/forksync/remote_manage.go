@@ -0,0 +1,70 @@
+package forksync
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+)
+
+// ensureRemote makes sure remoteName points at cloneURL, without silently
+// ignoring a stale or incorrect existing remote:
+//   - if remoteName doesn't exist, it's added
+//   - if it exists and already points at cloneURL, nothing happens
+//   - if it exists and points elsewhere, it's left alone (and an error is
+//     returned) unless replace is set, in which case its URL is updated
+//
+// This is the --shell fallback; syncGoGit uses ensureGoGitRemote instead.
+func ensureRemote(gitBin, dir, remoteName, cloneURL string, replace bool) error {
+	getCmd := exec.Command(gitBin, "remote", "get-url", remoteName)
+	getCmd.Dir = dir
+	output, err := getCmd.CombinedOutput()
+	if err != nil {
+		return runGitCommand(gitBin, GitCommand{
+			Args:        []string{"remote", "add", remoteName, cloneURL},
+			Description: fmt.Sprintf("adding %s remote", remoteName),
+			Dir:         dir,
+		})
+	}
+
+	existingURL := strings.TrimSpace(string(output))
+	if existingURL == cloneURL {
+		return nil
+	}
+
+	if !replace {
+		return fmt.Errorf("remote %q already points to %s, not %s (use --replace-remote to update it)", remoteName, existingURL, cloneURL)
+	}
+
+	return runGitCommand(gitBin, GitCommand{
+		Args:        []string{"remote", "set-url", remoteName, cloneURL},
+		Description: fmt.Sprintf("updating %s remote", remoteName),
+		Dir:         dir,
+	})
+}
+
+// ensureGoGitRemote is ensureRemote's go-git equivalent, used by syncGoGit.
+func ensureGoGitRemote(repo *git.Repository, remoteName, cloneURL string, replace bool) error {
+	remote, err := repo.Remote(remoteName)
+	if err == nil {
+		urls := remote.Config().URLs
+		if len(urls) > 0 && urls[0] == cloneURL {
+			return nil
+		}
+		if !replace {
+			return fmt.Errorf("remote %q already points to %s, not %s (use --replace-remote to update it)", remoteName, urls, cloneURL)
+		}
+		if err := repo.DeleteRemote(remoteName); err != nil {
+			return fmt.Errorf("replacing %s remote: %v", remoteName, err)
+		}
+	} else if err != git.ErrRemoteNotFound {
+		return fmt.Errorf("looking up %s remote: %v", remoteName, err)
+	}
+
+	if _, err := repo.CreateRemote(&config.RemoteConfig{Name: remoteName, URLs: []string{cloneURL}}); err != nil {
+		return fmt.Errorf("adding %s remote: %v", remoteName, err)
+	}
+	return nil
+}
@@ -0,0 +1,51 @@
+package forksync
+
+import (
+	"os/exec"
+	"testing"
+)
+
+func TestEnsureRemote(t *testing.T) {
+	tmpDir := t.TempDir()
+	if err := exec.Command("git", "init", tmpDir).Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	gitBin, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git not available")
+	}
+
+	getURL := func(t *testing.T, name string) string {
+		t.Helper()
+		cmd := exec.Command(gitBin, "remote", "get-url", name)
+		cmd.Dir = tmpDir
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(out)
+	}
+
+	if err := ensureRemote(gitBin, tmpDir, "upstream", "https://example.com/owner/repo.git", false); err != nil {
+		t.Fatalf("ensureRemote() error = %v", err)
+	}
+	if got := getURL(t, "upstream"); got != "https://example.com/owner/repo.git\n" {
+		t.Errorf("remote url after add = %q", got)
+	}
+
+	if err := ensureRemote(gitBin, tmpDir, "upstream", "https://example.com/owner/repo.git", false); err != nil {
+		t.Errorf("ensureRemote() on already-correct remote error = %v", err)
+	}
+
+	if err := ensureRemote(gitBin, tmpDir, "upstream", "https://example.com/owner/other.git", false); err == nil {
+		t.Error("expected an error when remote points elsewhere and replace is false")
+	}
+
+	if err := ensureRemote(gitBin, tmpDir, "upstream", "https://example.com/owner/other.git", true); err != nil {
+		t.Fatalf("ensureRemote() with replace error = %v", err)
+	}
+	if got := getURL(t, "upstream"); got != "https://example.com/owner/other.git\n" {
+		t.Errorf("remote url after replace = %q", got)
+	}
+}
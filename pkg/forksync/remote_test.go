@@ -0,0 +1,143 @@
+package forksync
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+)
+
+func TestHostRemoteParser(t *testing.T) {
+	parser := NewRemoteParser("github.com")
+
+	tests := []struct {
+		name          string
+		remoteURL     string
+		expectedOwner string
+		expectedRepo  string
+		wantErr       bool
+	}{
+		{
+			name:          "HTTPS URL",
+			remoteURL:     "https://github.com/owner/repo.git",
+			expectedOwner: "owner",
+			expectedRepo:  "repo",
+		},
+		{
+			name:          "HTTPS URL without .git suffix",
+			remoteURL:     "https://github.com/owner/repo",
+			expectedOwner: "owner",
+			expectedRepo:  "repo",
+		},
+		{
+			name:          "SCP-style shorthand",
+			remoteURL:     "git@github.com:owner/repo.git",
+			expectedOwner: "owner",
+			expectedRepo:  "repo",
+		},
+		{
+			name:          "ssh:// URL with port",
+			remoteURL:     "ssh://git@github.com:22/owner/repo.git",
+			expectedOwner: "owner",
+			expectedRepo:  "repo",
+		},
+		{
+			name:      "wrong host",
+			remoteURL: "https://gitlab.com/owner/repo.git",
+			wantErr:   true,
+		},
+		{
+			name:      "missing repo",
+			remoteURL: "https://github.com/owner",
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			owner, repo, err := parser.Parse(tt.remoteURL)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if tt.wantErr {
+				return
+			}
+			if owner != tt.expectedOwner {
+				t.Errorf("Parse() owner = %v, want %v", owner, tt.expectedOwner)
+			}
+			if repo != tt.expectedRepo {
+				t.Errorf("Parse() repo = %v, want %v", repo, tt.expectedRepo)
+			}
+		})
+	}
+}
+
+func TestGetOriginRepo(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "test-repo-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmpDir)
+
+	originalDir, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(originalDir)
+
+	if err := os.Chdir(tmpDir); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := exec.Command("git", "init").Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name          string
+		remoteURL     string
+		expectedOwner string
+		expectedRepo  string
+		wantErr       bool
+	}{
+		{
+			name:          "HTTPS URL",
+			remoteURL:     "https://github.com/owner/repo.git",
+			expectedOwner: "owner",
+			expectedRepo:  "repo",
+			wantErr:       false,
+		},
+		{
+			name:          "SSH URL",
+			remoteURL:     "git@github.com:owner/repo.git",
+			expectedOwner: "owner",
+			expectedRepo:  "repo",
+			wantErr:       false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cmd := exec.Command("git", "remote", "remove", "origin")
+			cmd.Run() // Ignore error if origin doesn't exist
+
+			cmd = exec.Command("git", "remote", "add", "origin", tt.remoteURL)
+			if err := cmd.Run(); err != nil {
+				t.Fatal(err)
+			}
+
+			owner, repo, err := GetOriginRepo("", NewRemoteParser("github.com"))
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetOriginRepo() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+
+			if owner != tt.expectedOwner {
+				t.Errorf("GetOriginRepo() owner = %v, want %v", owner, tt.expectedOwner)
+			}
+			if repo != tt.expectedRepo {
+				t.Errorf("GetOriginRepo() repo = %v, want %v", repo, tt.expectedRepo)
+			}
+		})
+	}
+}
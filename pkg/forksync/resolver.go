@@ -0,0 +1,158 @@
+package forksync
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// RepoInfo holds information about a forked repository and its parent.
+type RepoInfo struct {
+	FullName string `json:"full_name"`
+	Fork     bool   `json:"fork"`
+	Parent   struct {
+		FullName string `json:"full_name"`
+		CloneURL string `json:"clone_url"`
+	} `json:"parent"`
+}
+
+// validateFork checks if the repository is a fork
+func validateFork(info *RepoInfo) error {
+	if !info.Fork {
+		return fmt.Errorf("repository %s isn't a fork", info.FullName)
+	}
+	return nil
+}
+
+// ForkResolver fetches fork/parent information for owner/repoName from a git
+// hosting provider's API.
+type ForkResolver interface {
+	ResolveFork(owner, repoName string) (*RepoInfo, error)
+}
+
+// newForkResolver builds the ForkResolver for hostType, talking to host.
+func newForkResolver(host, hostType string) (ForkResolver, error) {
+	client, err := api.NewRESTClient(api.ClientOptions{Host: host})
+	if err != nil {
+		return nil, fmt.Errorf("initializing API client: %v", err)
+	}
+
+	switch hostType {
+	case "", "github", "gitea":
+		// Gitea's repo API mirrors GitHub's repos/{owner}/{repo} shape closely
+		// enough to share the same resolver.
+		return githubForkResolver{client: client}, nil
+	case "gitlab":
+		return gitlabForkResolver{client: client}, nil
+	case "bitbucket":
+		return bitbucketForkResolver{client: client}, nil
+	default:
+		return nil, fmt.Errorf("unsupported --host-type %q", hostType)
+	}
+}
+
+// githubForkResolver implements ForkResolver against the GitHub (or Gitea) REST API.
+type githubForkResolver struct {
+	client *api.RESTClient
+}
+
+func (r githubForkResolver) ResolveFork(owner, repoName string) (*RepoInfo, error) {
+	return getRepoInfo(r.client, owner, repoName)
+}
+
+// getRepoInfo fetches repository information from the GitHub (or Gitea) REST API
+func getRepoInfo(client *api.RESTClient, owner, repoName string) (*RepoInfo, error) {
+	info := &RepoInfo{}
+	err := client.Get(fmt.Sprintf("repos/%s/%s", owner, repoName), info)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repo info: %v", err)
+	}
+	return info, nil
+}
+
+// gitlabProject is the subset of a GitLab project resource we need.
+type gitlabProject struct {
+	PathWithNamespace string `json:"path_with_namespace"`
+	ForkedFromProject *struct {
+		PathWithNamespace string `json:"path_with_namespace"`
+		HTTPURLToRepo     string `json:"http_url_to_repo"`
+	} `json:"forked_from_project"`
+}
+
+// gitlabForkResolver implements ForkResolver against the GitLab REST API, reading
+// the parent project from forked_from_project.http_url_to_repo.
+type gitlabForkResolver struct {
+	client *api.RESTClient
+}
+
+func (r gitlabForkResolver) ResolveFork(owner, repoName string) (*RepoInfo, error) {
+	project := &gitlabProject{}
+	id := url.QueryEscape(fmt.Sprintf("%s/%s", owner, repoName))
+	if err := r.client.Get(fmt.Sprintf("projects/%s?statistics=false", id), project); err != nil {
+		return nil, fmt.Errorf("failed to get repo info: %v", err)
+	}
+
+	info := &RepoInfo{FullName: project.PathWithNamespace, Fork: project.ForkedFromProject != nil}
+	if project.ForkedFromProject != nil {
+		info.Parent.FullName = project.ForkedFromProject.PathWithNamespace
+		info.Parent.CloneURL = project.ForkedFromProject.HTTPURLToRepo
+	}
+	return info, nil
+}
+
+// bitbucketCloneLink is one entry of a Bitbucket repository's links.clone array.
+type bitbucketCloneLink struct {
+	Name string `json:"name"`
+	Href string `json:"href"`
+}
+
+// bitbucketRepo is the subset of a Bitbucket Cloud repository resource we need.
+type bitbucketRepo struct {
+	FullName string `json:"full_name"`
+	Parent   *struct {
+		FullName string `json:"full_name"`
+	} `json:"parent"`
+	Links struct {
+		Clone []bitbucketCloneLink `json:"clone"`
+	} `json:"links"`
+}
+
+// bitbucketForkResolver implements ForkResolver against the Bitbucket Cloud REST API.
+// Bitbucket's repository resource only names its parent, so the parent's own clone
+// URL needs a second lookup.
+type bitbucketForkResolver struct {
+	client *api.RESTClient
+}
+
+func (r bitbucketForkResolver) ResolveFork(owner, repoName string) (*RepoInfo, error) {
+	repo := &bitbucketRepo{}
+	if err := r.client.Get(fmt.Sprintf("repositories/%s/%s", owner, repoName), repo); err != nil {
+		return nil, fmt.Errorf("failed to get repo info: %v", err)
+	}
+
+	info := &RepoInfo{FullName: repo.FullName, Fork: repo.Parent != nil}
+	if repo.Parent != nil {
+		parent := &bitbucketRepo{}
+		if err := r.client.Get(fmt.Sprintf("repositories/%s", repo.Parent.FullName), parent); err != nil {
+			return nil, fmt.Errorf("failed to get parent repo info: %v", err)
+		}
+		cloneURL := cloneHref(parent.Links.Clone, "https")
+		if cloneURL == "" {
+			return nil, fmt.Errorf("parent repo %s has no https clone link", parent.FullName)
+		}
+		info.Parent.FullName = parent.FullName
+		info.Parent.CloneURL = cloneURL
+	}
+	return info, nil
+}
+
+// cloneHref returns the href of the named clone link, or "" if absent.
+func cloneHref(clones []bitbucketCloneLink, name string) string {
+	for _, c := range clones {
+		if c.Name == name {
+			return c.Href
+		}
+	}
+	return ""
+}
@@ -0,0 +1,209 @@
+package forksync
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/cli/go-gh/v2/pkg/api"
+)
+
+// stubTransport returns the given response bodies in order, one per request,
+// as 200 OK JSON responses. It fails the test if more requests come in than
+// responses were supplied.
+type stubTransport struct {
+	t         *testing.T
+	responses []string
+	calls     int
+}
+
+func (s *stubTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if s.calls >= len(s.responses) {
+		s.t.Fatalf("unexpected request %d: %s", s.calls+1, req.URL)
+	}
+	body := s.responses[s.calls]
+	s.calls++
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Body:       io.NopCloser(strings.NewReader(body)),
+		Header:     make(http.Header),
+	}, nil
+}
+
+// newTestRESTClient builds an api.RESTClient whose requests are served, in
+// order, from responses instead of hitting a real host.
+func newTestRESTClient(t *testing.T, responses ...string) *api.RESTClient {
+	t.Helper()
+	client, err := api.NewRESTClient(api.ClientOptions{
+		Host:      "example.com",
+		AuthToken: "test-token",
+		Transport: &stubTransport{t: t, responses: responses},
+	})
+	if err != nil {
+		t.Fatalf("NewRESTClient() error = %v", err)
+	}
+	return client
+}
+
+func TestValidateFork(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    *RepoInfo
+		wantErr bool
+	}{
+		{
+			name: "valid fork",
+			info: &RepoInfo{
+				FullName: "user/repo",
+				Fork:     true,
+			},
+			wantErr: false,
+		},
+		{
+			name: "not a fork",
+			info: &RepoInfo{
+				FullName: "user/repo",
+				Fork:     false,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateFork(tt.info)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("validateFork() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGithubForkResolverResolveFork(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFork   bool
+		wantParent string
+		wantClone  string
+	}{
+		{
+			name:       "fork",
+			body:       `{"full_name":"me/repo","fork":true,"parent":{"full_name":"upstream/repo","clone_url":"https://example.com/upstream/repo.git"}}`,
+			wantFork:   true,
+			wantParent: "upstream/repo",
+			wantClone:  "https://example.com/upstream/repo.git",
+		},
+		{
+			name:     "not a fork",
+			body:     `{"full_name":"me/repo","fork":false}`,
+			wantFork: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := githubForkResolver{client: newTestRESTClient(t, tt.body)}
+			info, err := resolver.ResolveFork("me", "repo")
+			if err != nil {
+				t.Fatalf("ResolveFork() error = %v", err)
+			}
+			if info.Fork != tt.wantFork {
+				t.Errorf("Fork = %v, want %v", info.Fork, tt.wantFork)
+			}
+			if info.Parent.FullName != tt.wantParent {
+				t.Errorf("Parent.FullName = %q, want %q", info.Parent.FullName, tt.wantParent)
+			}
+			if info.Parent.CloneURL != tt.wantClone {
+				t.Errorf("Parent.CloneURL = %q, want %q", info.Parent.CloneURL, tt.wantClone)
+			}
+		})
+	}
+}
+
+func TestGitlabForkResolverResolveFork(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantFork   bool
+		wantParent string
+		wantClone  string
+	}{
+		{
+			name:       "fork",
+			body:       `{"path_with_namespace":"me/repo","forked_from_project":{"path_with_namespace":"upstream/repo","http_url_to_repo":"https://example.com/upstream/repo.git"}}`,
+			wantFork:   true,
+			wantParent: "upstream/repo",
+			wantClone:  "https://example.com/upstream/repo.git",
+		},
+		{
+			name:     "not a fork",
+			body:     `{"path_with_namespace":"me/repo"}`,
+			wantFork: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := gitlabForkResolver{client: newTestRESTClient(t, tt.body)}
+			info, err := resolver.ResolveFork("me", "repo")
+			if err != nil {
+				t.Fatalf("ResolveFork() error = %v", err)
+			}
+			if info.Fork != tt.wantFork {
+				t.Errorf("Fork = %v, want %v", info.Fork, tt.wantFork)
+			}
+			if info.Parent.FullName != tt.wantParent {
+				t.Errorf("Parent.FullName = %q, want %q", info.Parent.FullName, tt.wantParent)
+			}
+			if info.Parent.CloneURL != tt.wantClone {
+				t.Errorf("Parent.CloneURL = %q, want %q", info.Parent.CloneURL, tt.wantClone)
+			}
+		})
+	}
+}
+
+func TestBitbucketForkResolverResolveFork(t *testing.T) {
+	t.Run("fork with https clone link", func(t *testing.T) {
+		childBody := `{"full_name":"me/repo","parent":{"full_name":"upstream/repo"}}`
+		parentBody := `{"full_name":"upstream/repo","links":{"clone":[` +
+			`{"name":"https","href":"https://example.com/upstream/repo.git"},` +
+			`{"name":"ssh","href":"git@example.com:upstream/repo.git"}]}}`
+		resolver := bitbucketForkResolver{client: newTestRESTClient(t, childBody, parentBody)}
+
+		info, err := resolver.ResolveFork("me", "repo")
+		if err != nil {
+			t.Fatalf("ResolveFork() error = %v", err)
+		}
+		if !info.Fork {
+			t.Error("Fork = false, want true")
+		}
+		if info.Parent.CloneURL != "https://example.com/upstream/repo.git" {
+			t.Errorf("Parent.CloneURL = %q, want the https clone link", info.Parent.CloneURL)
+		}
+	})
+
+	t.Run("fork with no https clone link errors", func(t *testing.T) {
+		childBody := `{"full_name":"me/repo","parent":{"full_name":"upstream/repo"}}`
+		parentBody := `{"full_name":"upstream/repo","links":{"clone":[{"name":"ssh","href":"git@example.com:upstream/repo.git"}]}}`
+		resolver := bitbucketForkResolver{client: newTestRESTClient(t, childBody, parentBody)}
+
+		if _, err := resolver.ResolveFork("me", "repo"); err == nil {
+			t.Error("expected an error when the parent has no https clone link")
+		}
+	})
+
+	t.Run("not a fork", func(t *testing.T) {
+		childBody := `{"full_name":"me/repo"}`
+		resolver := bitbucketForkResolver{client: newTestRESTClient(t, childBody)}
+
+		info, err := resolver.ResolveFork("me", "repo")
+		if err != nil {
+			t.Fatalf("ResolveFork() error = %v", err)
+		}
+		if info.Fork {
+			t.Error("Fork = true, want false")
+		}
+	})
+}